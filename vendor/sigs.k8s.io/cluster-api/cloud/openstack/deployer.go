@@ -0,0 +1,191 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openstack contains the OpenStack implementation of the
+// clusterctl deployer used to bootstrap and query clusters backed by
+// gophercloud.
+package openstack
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	clientconfig "github.com/gophercloud/utils/openstack/clientconfig"
+	"golang.org/x/crypto/ssh"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	// remoteAdminConf is the location of the admin kubeconfig on a master
+	// node created by kubeadm.
+	remoteAdminConf = "/etc/kubernetes/admin.conf"
+	sshUser         = "ubuntu"
+)
+
+// DeploymentClient talks to the OpenStack Compute and Network APIs on
+// behalf of clusterctl. It is intentionally independent of the
+// in-cluster machine actuator so that clusterctl can run before any
+// provider components exist in the target cluster.
+type DeploymentClient struct {
+	computeClient *gophercloud.ServiceClient
+	networkClient *gophercloud.ServiceClient
+}
+
+// NewDeploymentClient builds a DeploymentClient using the same
+// clouds.yaml/environment variable resolution as the OpenStack CLI, so
+// operators can point clusterctl at the cloud with OS_CLOUD or
+// OS_AUTH_URL/OS_USERNAME/OS_PASSWORD like any other OpenStack tool.
+func NewDeploymentClient() *DeploymentClient {
+	opts := &clientconfig.ClientOpts{}
+	providerClient, clientOpts, err := clientconfig.AuthenticatedClient(opts)
+	if err != nil {
+		glog.Fatalf("Error creating OpenStack client: %v", err)
+	}
+
+	compute, err := openstack.NewComputeV2(providerClient, gophercloud.EndpointOpts{
+		Region: clientOpts.RegionName,
+	})
+	if err != nil {
+		glog.Fatalf("Error creating OpenStack compute client: %v", err)
+	}
+
+	network, err := openstack.NewNetworkV2(providerClient, gophercloud.EndpointOpts{
+		Region: clientOpts.RegionName,
+	})
+	if err != nil {
+		glog.Fatalf("Error creating OpenStack network client: %v", err)
+	}
+
+	return &DeploymentClient{
+		computeClient: compute,
+		networkClient: network,
+	}
+}
+
+// GetIP returns the floating IP attached to the server backing machine.
+func (d *DeploymentClient) GetIP(machine *clusterv1.Machine) (string, error) {
+	server, err := d.getServer(machine)
+	if err != nil {
+		return "", err
+	}
+
+	pages, err := floatingips.List(d.computeClient).AllPages()
+	if err != nil {
+		return "", fmt.Errorf("error listing floating ips: %v", err)
+	}
+	fips, err := floatingips.ExtractFloatingIPs(pages)
+	if err != nil {
+		return "", fmt.Errorf("error extracting floating ips: %v", err)
+	}
+
+	for _, fip := range fips {
+		if fip.InstanceID == server.ID {
+			return fip.IP, nil
+		}
+	}
+
+	return "", fmt.Errorf("no floating ip found for machine %s (server %s)", machine.Name, server.ID)
+}
+
+// GetKubeConfig scp's the admin kubeconfig off of the master via its
+// floating IP so clusterctl can talk to the freshly bootstrapped
+// cluster without any provider components installed yet.
+func (d *DeploymentClient) GetKubeConfig(master *clusterv1.Machine) (string, error) {
+	ip, err := d.GetIP(master)
+	if err != nil {
+		return "", fmt.Errorf("error getting IP for master %s: %v", master.Name, err)
+	}
+
+	return d.scp(ip, remoteAdminConf)
+}
+
+func (d *DeploymentClient) getServer(machine *clusterv1.Machine) (*servers.Server, error) {
+	pages, err := servers.List(d.computeClient, servers.ListOpts{Name: machine.Name}).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers for machine %s: %v", machine.Name, err)
+	}
+	serverList, err := servers.ExtractServers(pages)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting servers for machine %s: %v", machine.Name, err)
+	}
+	if len(serverList) == 0 {
+		return nil, fmt.Errorf("no server found for machine %s", machine.Name)
+	}
+
+	return &serverList[0], nil
+}
+
+// scp reads remotePath off of host over SSH using the private key at
+// OPENSTACK_SSH_KEY (falling back to ~/.ssh/id_rsa), matching the key
+// injected into the master's cloud-init user data at boot.
+func (d *DeploymentClient) scp(host, remotePath string) (string, error) {
+	key, err := loadPrivateKey()
+	if err != nil {
+		return "", err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            sshUser,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:22", host), config)
+	if err != nil {
+		return "", fmt.Errorf("error dialing %s: %v", host, err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("error creating ssh session to %s: %v", host, err)
+	}
+	defer session.Close()
+
+	var out bytes.Buffer
+	session.Stdout = &out
+	if err := session.Run(fmt.Sprintf("sudo cat %s", remotePath)); err != nil {
+		return "", fmt.Errorf("error reading %s from %s: %v", remotePath, host, err)
+	}
+
+	return out.String(), nil
+}
+
+func loadPrivateKey() (ssh.Signer, error) {
+	path := os.Getenv("OPENSTACK_SSH_KEY")
+	if path == "" {
+		path = os.Getenv("HOME") + "/.ssh/id_rsa"
+	}
+
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ssh key %s: %v", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ssh key %s: %v", path, err)
+	}
+
+	return signer, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cloud/google"
+	"sigs.k8s.io/cluster-api/cloud/openstack"
 	"sigs.k8s.io/cluster-api/cloud/vsphere"
 	"sigs.k8s.io/cluster-api/clusterctl/clusterdeployer"
 	"sigs.k8s.io/cluster-api/clusterctl/clusterdeployer/minikube"
@@ -109,7 +110,7 @@ func init() {
 	createClusterCmd.Flags().StringVarP(&co.Machine, "machines", "m", "", "A yaml file containing machine object definition(s)")
 	createClusterCmd.Flags().StringVarP(&co.ProviderComponents, "provider-components", "p", "", "A yaml file containing cluster api provider controllers and supporting objects")
 	// TODO: Remove as soon as code allows https://github.com/kubernetes-sigs/cluster-api/issues/157
-	createClusterCmd.Flags().StringVarP(&co.Provider, "provider", "", "", "Which provider deployment logic to use (google/vsphere/azure)")
+	createClusterCmd.Flags().StringVarP(&co.Provider, "provider", "", "", "Which provider deployment logic to use (google/vsphere/openstack/azure)")
 
 	// Optional flags
 	createClusterCmd.Flags().StringVarP(&co.AddonComponents, "addon-components", "a", "", "A yaml file containing cluster addons to apply to the internal cluster")
@@ -160,6 +161,8 @@ func getProvider(provider string) (clusterdeployer.ProviderDeployer, error) {
 		return google.NewMachineActuator(google.MachineActuatorParams{})
 	case "vsphere":
 		return &vsphereAdapter{vsphere.NewDeploymentClient()}, nil
+	case "openstack":
+		return &openstackAdapter{openstack.NewDeploymentClient()}, nil
 	case "azure":
 		//Work being done at https://github.com/platform9/azure-provider
 		return nil, errors.New("Azure not yet implemented")
@@ -182,3 +185,18 @@ func (a *vsphereAdapter) GetIP(cluster *clusterv1.Cluster, machine *clusterv1.Ma
 func (a *vsphereAdapter) GetKubeConfig(cluster *clusterv1.Cluster, master *clusterv1.Machine) (string, error) {
 	return a.DeploymentClient.GetKubeConfig(master)
 }
+
+// Adapt the openstack methods calls to the ProviderDeployer interface, since
+// the OpenStack DeploymentClient's methods take only the machine and don't
+// need the cluster to resolve a server or its floating IP.
+type openstackAdapter struct {
+	*openstack.DeploymentClient
+}
+
+func (a *openstackAdapter) GetIP(cluster *clusterv1.Cluster, machine *clusterv1.Machine) (string, error) {
+	return a.DeploymentClient.GetIP(machine)
+}
+
+func (a *openstackAdapter) GetKubeConfig(cluster *clusterv1.Cluster, master *clusterv1.Machine) (string, error) {
+	return a.DeploymentClient.GetKubeConfig(master)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/clusterctl/clusterdeployer"
+	"sigs.k8s.io/cluster-api/clusterctl/clusterdeployer/minikube"
+)
+
+type DeleteOptions struct {
+	Cluster                string
+	Machine                string
+	ProviderComponents     string
+	CleanupExternalCluster bool
+	VmDriver               string
+	Provider               string
+	KubeconfigOutput       string
+}
+
+var do = &DeleteOptions{}
+
+var deleteClusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Delete a kubernetes cluster",
+	Long:  `Delete a kubernetes cluster and release the infrastructure it holds`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if do.Cluster == "" {
+			exitWithHelp(cmd, "Please provide yaml file for cluster definition.")
+		}
+		if do.Machine == "" {
+			exitWithHelp(cmd, "Please provide yaml file for machine definition.")
+		}
+		if do.ProviderComponents == "" {
+			exitWithHelp(cmd, "Please provide yaml file for provider component definition.")
+		}
+		if err := RunDelete(do); err != nil {
+			glog.Exit(err)
+		}
+	},
+}
+
+func RunDelete(do *DeleteOptions) error {
+	c, err := parseClusterYaml(do.Cluster)
+	if err != nil {
+		return err
+	}
+	m, err := parseMachinesYaml(do.Machine)
+	if err != nil {
+		return err
+	}
+
+	mini := minikube.New(do.VmDriver)
+	pd, err := getProvider(do.Provider)
+	if err != nil {
+		return err
+	}
+	pc, err := ioutil.ReadFile(do.ProviderComponents)
+	if err != nil {
+		return fmt.Errorf("error loading provider components file '%v': %v", do.ProviderComponents, err)
+	}
+
+	pcsFactory := clusterdeployer.NewProviderComponentsStoreFactory()
+	d := clusterdeployer.New(
+		mini,
+		clusterdeployer.NewClientFactory(),
+		pd,
+		string(pc),
+		"",
+		do.KubeconfigOutput,
+		do.CleanupExternalCluster)
+	return d.Delete(c, m, pcsFactory)
+}
+
+func init() {
+	// Required flags
+	deleteClusterCmd.Flags().StringVarP(&do.Cluster, "cluster", "c", "", "A yaml file containing cluster object definition")
+	deleteClusterCmd.Flags().StringVarP(&do.Machine, "machines", "m", "", "A yaml file containing machine object definition(s)")
+	deleteClusterCmd.Flags().StringVarP(&do.ProviderComponents, "provider-components", "p", "", "A yaml file containing cluster api provider controllers and supporting objects")
+	deleteClusterCmd.Flags().StringVarP(&do.Provider, "provider", "", "", "Which provider deployment logic to use (google/vsphere/openstack/azure)")
+
+	// Optional flags
+	deleteClusterCmd.Flags().BoolVarP(&do.CleanupExternalCluster, "cleanup-external-cluster", "", true, "Whether to cleanup the external cluster after deletion")
+	deleteClusterCmd.Flags().StringVarP(&do.VmDriver, "vm-driver", "", "", "Which vm driver to use for minikube")
+	deleteClusterCmd.Flags().StringVarP(&do.KubeconfigOutput, "kubeconfig-out", "", "kubeconfig", "Where the kubeconfig for the cluster being deleted can be found")
+
+	deleteCmd.AddCommand(deleteClusterCmd)
+}
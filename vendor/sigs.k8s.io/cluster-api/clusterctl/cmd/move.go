@@ -0,0 +1,357 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	clientv1alpha1 "sigs.k8s.io/cluster-api/pkg/client/clientset_generated/clientset/typed/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/clientcmd"
+)
+
+// pausedAnnotation marks a Cluster whose reconciliation has been paused so
+// that the source management cluster's controllers stop acting on it while
+// the objects are copied to the target.
+const pausedAnnotation = "cluster.k8s.io/paused"
+
+type MoveOptions struct {
+	SourceKubeconfig string
+	TargetKubeconfig string
+	Namespace        string
+}
+
+var mo = &MoveOptions{}
+
+var moveCmd = &cobra.Command{
+	Use:   "move",
+	Short: "Move Cluster API objects and all dependencies between two management clusters",
+	Long: `Move pivots a management cluster's Cluster API objects (Clusters,
+MachineDeployments, MachineSets, Machines, and every Secret in the namespace)
+from the source kubeconfig to the target kubeconfig, pausing reconciliation
+on the source until the target has adopted every object.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if mo.TargetKubeconfig == "" {
+			exitWithHelp(cmd, "Please provide a target kubeconfig to move objects to.")
+		}
+		if err := RunMove(mo); err != nil {
+			glog.Exit(err)
+		}
+	},
+}
+
+func init() {
+	moveCmd.Flags().StringVarP(&mo.SourceKubeconfig, "kubeconfig", "", "", "Path to the kubeconfig for the source management cluster")
+	moveCmd.Flags().StringVarP(&mo.TargetKubeconfig, "target-kubeconfig", "", "", "Path to the kubeconfig for the target management cluster")
+	moveCmd.Flags().StringVarP(&mo.Namespace, "namespace", "n", v1.NamespaceDefault, "Namespace whose objects should be moved")
+
+	rootCmd.AddCommand(moveCmd)
+}
+
+// RunMove pivots every Cluster API object in mo.Namespace from the source
+// management cluster to the target one.
+func RunMove(mo *MoveOptions) error {
+	source, err := newMoveClients(mo.SourceKubeconfig)
+	if err != nil {
+		return fmt.Errorf("error creating client for source cluster: %v", err)
+	}
+	target, err := newMoveClients(mo.TargetKubeconfig)
+	if err != nil {
+		return fmt.Errorf("error creating client for target cluster: %v", err)
+	}
+
+	clusters, err := source.cluster.Clusters(mo.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing clusters: %v", err)
+	}
+
+	for i := range clusters.Items {
+		cluster := clusters.Items[i].DeepCopy()
+		glog.Infof("Pausing reconciliation of cluster %q on the source management cluster.", cluster.Name)
+		if err := pauseCluster(source.cluster, cluster); err != nil {
+			return err
+		}
+	}
+
+	secrets, err := source.core.CoreV1().Secrets(mo.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing secrets: %v", err)
+	}
+
+	// ownerUIDs tracks the UID each recreated object was assigned by the
+	// target apiserver, keyed by (kind, name), so that dependents recreated
+	// later can have their OwnerReferences re-pointed at the new UIDs
+	// instead of the now-nonexistent ones from the source.
+	ownerUIDs := map[ownerKey]types.UID{}
+
+	for i := range clusters.Items {
+		cluster := clusters.Items[i].DeepCopy()
+		clearAnnotation(cluster, pausedAnnotation)
+		ownerRefs := cluster.OwnerReferences
+		cluster.OwnerReferences = nil
+		stripMeta(&cluster.ObjectMeta)
+		glog.Infof("Recreating cluster %q on the target management cluster.", cluster.Name)
+		created, err := target.cluster.Clusters(mo.Namespace).Create(cluster)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error recreating cluster %q on target: %v", cluster.Name, err)
+		}
+		if created == nil {
+			created, err = target.cluster.Clusters(mo.Namespace).Get(cluster.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error fetching existing cluster %q on target: %v", cluster.Name, err)
+			}
+		}
+		ownerUIDs[ownerKey{Kind: "Cluster", Name: created.Name}] = created.UID
+		if len(ownerRefs) > 0 {
+			created.OwnerReferences = mapOwnerReferences(ownerRefs, ownerUIDs)
+			if _, err := target.cluster.Clusters(mo.Namespace).Update(created); err != nil {
+				return fmt.Errorf("error re-linking owner references for cluster %q on target: %v", cluster.Name, err)
+			}
+		}
+	}
+
+	for i := range secrets.Items {
+		secret := secrets.Items[i].DeepCopy()
+		ownerRefs := secret.OwnerReferences
+		secret.OwnerReferences = nil
+		stripMeta(&secret.ObjectMeta)
+		created, err := target.core.CoreV1().Secrets(mo.Namespace).Create(secret)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error recreating secret %q on target: %v", secret.Name, err)
+		}
+		if created == nil {
+			created, err = target.core.CoreV1().Secrets(mo.Namespace).Get(secret.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error fetching existing secret %q on target: %v", secret.Name, err)
+			}
+		}
+		if len(ownerRefs) > 0 {
+			created.OwnerReferences = mapOwnerReferences(ownerRefs, ownerUIDs)
+			if _, err := target.core.CoreV1().Secrets(mo.Namespace).Update(created); err != nil {
+				return fmt.Errorf("error re-linking owner references for secret %q on target: %v", secret.Name, err)
+			}
+		}
+	}
+
+	machineDeployments, err := source.cluster.MachineDeployments(mo.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing machinedeployments: %v", err)
+	}
+	for i := range machineDeployments.Items {
+		md := machineDeployments.Items[i].DeepCopy()
+		ownerRefs := md.OwnerReferences
+		md.OwnerReferences = nil
+		stripMeta(&md.ObjectMeta)
+		created, err := target.cluster.MachineDeployments(mo.Namespace).Create(md)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error recreating machinedeployment %q on target: %v", md.Name, err)
+		}
+		if created == nil {
+			created, err = target.cluster.MachineDeployments(mo.Namespace).Get(md.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error fetching existing machinedeployment %q on target: %v", md.Name, err)
+			}
+		}
+		ownerUIDs[ownerKey{Kind: "MachineDeployment", Name: created.Name}] = created.UID
+		if len(ownerRefs) > 0 {
+			created.OwnerReferences = mapOwnerReferences(ownerRefs, ownerUIDs)
+			if _, err := target.cluster.MachineDeployments(mo.Namespace).Update(created); err != nil {
+				return fmt.Errorf("error re-linking owner references for machinedeployment %q on target: %v", md.Name, err)
+			}
+		}
+	}
+
+	machineSets, err := source.cluster.MachineSets(mo.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing machinesets: %v", err)
+	}
+	for i := range machineSets.Items {
+		ms := machineSets.Items[i].DeepCopy()
+		ownerRefs := ms.OwnerReferences
+		ms.OwnerReferences = nil
+		stripMeta(&ms.ObjectMeta)
+		created, err := target.cluster.MachineSets(mo.Namespace).Create(ms)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error recreating machineset %q on target: %v", ms.Name, err)
+		}
+		if created == nil {
+			created, err = target.cluster.MachineSets(mo.Namespace).Get(ms.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error fetching existing machineset %q on target: %v", ms.Name, err)
+			}
+		}
+		ownerUIDs[ownerKey{Kind: "MachineSet", Name: created.Name}] = created.UID
+		if len(ownerRefs) > 0 {
+			created.OwnerReferences = mapOwnerReferences(ownerRefs, ownerUIDs)
+			if _, err := target.cluster.MachineSets(mo.Namespace).Update(created); err != nil {
+				return fmt.Errorf("error re-linking owner references for machineset %q on target: %v", ms.Name, err)
+			}
+		}
+	}
+
+	machines, err := source.cluster.Machines(mo.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing machines: %v", err)
+	}
+	for i := range machines.Items {
+		machine := machines.Items[i].DeepCopy()
+		ownerRefs := machine.OwnerReferences
+		machine.OwnerReferences = nil
+		stripMeta(&machine.ObjectMeta)
+		created, err := target.cluster.Machines(mo.Namespace).Create(machine)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("error recreating machine %q on target: %v", machine.Name, err)
+		}
+		if created == nil {
+			created, err = target.cluster.Machines(mo.Namespace).Get(machine.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error fetching existing machine %q on target: %v", machine.Name, err)
+			}
+		}
+		if len(ownerRefs) > 0 {
+			created.OwnerReferences = mapOwnerReferences(ownerRefs, ownerUIDs)
+			if _, err := target.cluster.Machines(mo.Namespace).Update(created); err != nil {
+				return fmt.Errorf("error re-linking owner references for machine %q on target: %v", machine.Name, err)
+			}
+		}
+	}
+
+	glog.Info("Waiting for the target management cluster to adopt the moved objects.")
+	if err := waitForAdoption(target.cluster, mo.Namespace, machines.Items); err != nil {
+		return err
+	}
+
+	glog.Info("Deleting moved objects from the source management cluster.")
+	return deleteFromSource(source, mo.Namespace, clusters.Items, machineDeployments.Items, machineSets.Items, machines.Items, secrets.Items)
+}
+
+type moveClients struct {
+	core    *kubernetes.Clientset
+	cluster clientv1alpha1.ClusterV1alpha1Interface
+}
+
+func newMoveClients(kubeconfig string) (*moveClients, error) {
+	coreClientset, clusterapiClientset, err := clientcmd.NewClientsForDefaultSearchpath(kubeconfig, clientcmd.NewConfigOverrides())
+	if err != nil {
+		return nil, err
+	}
+	return &moveClients{core: coreClientset, cluster: clusterapiClientset.ClusterV1alpha1()}, nil
+}
+
+func pauseCluster(client clientv1alpha1.ClusterV1alpha1Interface, cluster *clusterv1.Cluster) error {
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[pausedAnnotation] = "true"
+	_, err := client.Clusters(cluster.Namespace).Update(cluster)
+	return err
+}
+
+// clearAnnotation removes key from cluster's annotations, if present.
+func clearAnnotation(cluster *clusterv1.Cluster, key string) {
+	delete(cluster.Annotations, key)
+}
+
+// stripMeta clears the fields the API server assigns so an object can be
+// recreated on another cluster. OwnerReferences are handled separately by
+// the caller, since their UIDs must be re-linked once the owner has been
+// recreated on the target.
+func stripMeta(meta *metav1.ObjectMeta) {
+	meta.ResourceVersion = ""
+	meta.UID = ""
+	meta.SelfLink = ""
+	meta.CreationTimestamp = metav1.Time{}
+}
+
+// ownerKey identifies a recreated object so that dependents can look up its
+// new UID on the target management cluster.
+type ownerKey struct {
+	Kind string
+	Name string
+}
+
+// mapOwnerReferences returns a copy of refs with each UID rewritten to the
+// new UID the owning object was assigned on the target, using ownerUIDs.
+// A reference whose owner hasn't been recreated yet (and so has no entry in
+// ownerUIDs) is passed through unchanged.
+func mapOwnerReferences(refs []metav1.OwnerReference, ownerUIDs map[ownerKey]types.UID) []metav1.OwnerReference {
+	relinked := make([]metav1.OwnerReference, len(refs))
+	for i, ref := range refs {
+		if uid, ok := ownerUIDs[ownerKey{Kind: ref.Kind, Name: ref.Name}]; ok {
+			ref.UID = uid
+		}
+		relinked[i] = ref
+	}
+	return relinked
+}
+
+// waitForAdoption polls the target cluster until every moved Machine has
+// been picked back up by the target's controllers, i.e. it has a NodeRef
+// again.
+func waitForAdoption(client clientv1alpha1.ClusterV1alpha1Interface, namespace string, machines []clusterv1.Machine) error {
+	return wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
+		for _, machine := range machines {
+			m, err := client.Machines(namespace).Get(machine.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			if m.Status.NodeRef == nil {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+func deleteFromSource(source *moveClients, namespace string, clusters []clusterv1.Cluster, machineDeployments []clusterv1.MachineDeployment, machineSets []clusterv1.MachineSet, machines []clusterv1.Machine, secrets []v1.Secret) error {
+	client := source.cluster
+	for _, machine := range machines {
+		if err := client.Machines(namespace).Delete(machine.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting machine %q from source: %v", machine.Name, err)
+		}
+	}
+	for _, ms := range machineSets {
+		if err := client.MachineSets(namespace).Delete(ms.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting machineset %q from source: %v", ms.Name, err)
+		}
+	}
+	for _, md := range machineDeployments {
+		if err := client.MachineDeployments(namespace).Delete(md.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting machinedeployment %q from source: %v", md.Name, err)
+		}
+	}
+	for _, cluster := range clusters {
+		if err := client.Clusters(namespace).Delete(cluster.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting cluster %q from source: %v", cluster.Name, err)
+		}
+	}
+	for _, secret := range secrets {
+		if err := source.core.CoreV1().Secrets(namespace).Delete(secret.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting secret %q from source: %v", secret.Name, err)
+		}
+	}
+	return nil
+}
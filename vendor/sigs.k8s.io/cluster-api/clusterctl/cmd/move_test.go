@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStripMeta(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:              "worker-1",
+		ResourceVersion:   "123",
+		UID:               types.UID("source-uid"),
+		SelfLink:          "/apis/cluster.k8s.io/v1alpha1/machines/worker-1",
+		CreationTimestamp: metav1.Time{},
+	}
+	stripMeta(&meta)
+
+	if meta.ResourceVersion != "" || meta.UID != "" || meta.SelfLink != "" {
+		t.Errorf("stripMeta left server-assigned fields set: %+v", meta)
+	}
+	if meta.Name != "worker-1" {
+		t.Errorf("stripMeta changed Name to %q, want %q", meta.Name, "worker-1")
+	}
+}
+
+func TestMapOwnerReferences(t *testing.T) {
+	refs := []metav1.OwnerReference{
+		{Kind: "Cluster", Name: "test-cluster", UID: types.UID("source-cluster-uid")},
+		{Kind: "MachineSet", Name: "unrecreated-ms", UID: types.UID("source-ms-uid")},
+	}
+	ownerUIDs := map[ownerKey]types.UID{
+		{Kind: "Cluster", Name: "test-cluster"}: types.UID("target-cluster-uid"),
+	}
+
+	relinked := mapOwnerReferences(refs, ownerUIDs)
+
+	if relinked[0].UID != types.UID("target-cluster-uid") {
+		t.Errorf("owner ref for recreated Cluster kept stale UID %q", relinked[0].UID)
+	}
+	if relinked[1].UID != types.UID("source-ms-uid") {
+		t.Errorf("owner ref for a not-yet-recreated owner should be left unchanged, got %q", relinked[1].UID)
+	}
+}
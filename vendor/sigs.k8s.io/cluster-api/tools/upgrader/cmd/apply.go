@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/tools/upgrader/util"
+)
+
+type ApplyOptions struct {
+	Kubeconfig      string
+	ToKubernetes    string
+	Strategy        string
+	MaxSurge        int
+	MaxUnavailable  int
+	DrainTimeout    time.Duration
+	AddonComponents string
+}
+
+var apo = &ApplyOptions{}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Upgrade a cluster to a specific Kubernetes version",
+	Long: `Apply executes a specific version transition against the target
+cluster, enforcing the kubelet/control plane version skew rules
+server-side before patching any Machine.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apo.ToKubernetes == "" {
+			exitWithHelp(cmd, "Please provide --to-kubernetes.")
+		}
+		options := util.UpgradeOptions{
+			KubeVersion:     apo.ToKubernetes,
+			Kubeconfig:      apo.Kubeconfig,
+			Strategy:        util.Strategy(apo.Strategy),
+			MaxSurge:        apo.MaxSurge,
+			MaxUnavailable:  apo.MaxUnavailable,
+			DrainTimeout:    apo.DrainTimeout,
+			AddonComponents: apo.AddonComponents,
+		}
+		if err := util.UpgradeClusterWithOptions(options); err != nil {
+			glog.Exit(err)
+		}
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&apo.Kubeconfig, "kubeconfig", "", "", "Path to the kubeconfig of the cluster to upgrade")
+	applyCmd.Flags().StringVarP(&apo.ToKubernetes, "to-kubernetes", "", "", "The Kubernetes version to upgrade to")
+	applyCmd.Flags().StringVarP(&apo.Strategy, "strategy", "", string(util.InPlace), "Worker update strategy to use: InPlace or Recreate")
+	applyCmd.Flags().IntVarP(&apo.MaxSurge, "max-surge", "", 1, "Maximum number of replacement machines the Recreate strategy creates above the existing worker count")
+	applyCmd.Flags().IntVarP(&apo.MaxUnavailable, "max-unavailable", "", 0, "Maximum number of workers the Recreate strategy may drain at once (0 is treated as 1, since 0 would never drain any worker)")
+	applyCmd.Flags().DurationVarP(&apo.DrainTimeout, "drain-timeout", "", 5*time.Minute, "How long the Recreate strategy waits for each pod on an old worker to be evicted")
+	applyCmd.Flags().StringVarP(&apo.AddonComponents, "addon-components", "a", "", "A yaml file containing cluster addons to re-apply after the control plane is upgraded")
+
+	upgradeCmd.AddCommand(applyCmd)
+}
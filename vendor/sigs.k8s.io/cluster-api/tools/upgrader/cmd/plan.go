@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/tools/upgrader/util"
+)
+
+type PlanOptions struct {
+	Kubeconfig   string
+	ToKubernetes string
+}
+
+var plo = &PlanOptions{}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Print the Machine version transitions an upgrade would make",
+	Long: `Plan lists every Machine in the default namespace of the target
+cluster and the version
+transition it would receive from "upgrade apply", without mutating
+anything, flagging any transition that would violate the kubelet/control
+plane version skew policy.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if plo.ToKubernetes == "" {
+			exitWithHelp(cmd, "Please provide --to-kubernetes.")
+		}
+		if err := RunPlan(plo); err != nil {
+			glog.Exit(err)
+		}
+	},
+}
+
+func init() {
+	planCmd.Flags().StringVarP(&plo.Kubeconfig, "kubeconfig", "", "", "Path to the kubeconfig of the cluster to plan an upgrade for")
+	planCmd.Flags().StringVarP(&plo.ToKubernetes, "to-kubernetes", "", "", "The Kubernetes version to plan an upgrade to")
+
+	upgradeCmd.AddCommand(planCmd)
+}
+
+// RunPlan prints the proposed transitions to stdout.
+func RunPlan(plo *PlanOptions) error {
+	transitions, err := util.Plan(plo.Kubeconfig, plo.ToKubernetes)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MACHINE\tROLE\tFROM\tTO\tWARNING")
+	for _, t := range transitions {
+		role := "node"
+		if t.IsMaster {
+			role = "master"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.MachineName, role, t.FromVersion, t.ToVersion, t.SkewWarning)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cmd implements the upgrader command line tool used to move a
+// Cluster API managed Kubernetes cluster onto a new version.
+package cmd
+
+import (
+	"github.com/golang/glog"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "upgrader",
+	Short: "Upgrade a Cluster API managed Kubernetes cluster",
+}
+
+func exitWithHelp(cmd *cobra.Command, message string) {
+	glog.Error(message)
+	cmd.Help()
+	glog.Exit(message)
+}
+
+// Execute runs the upgrader root command.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		glog.Exit(err)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	addonVersionsConfigMap = "addon-versions"
+	addonFieldManager      = "cluster-api-upgrader"
+)
+
+// applyAddons server-side applies every object in addonComponents (a
+// multi-document YAML file, the same format `clusterctl create cluster
+// --addon-components` accepts) against the target cluster and records the
+// file's sha256 in the addon-versions ConfigMap in kube-system so later
+// upgrades can tell whether the addons actually changed.
+func applyAddons(kubeconfig, addonComponents string) error {
+	if addonComponents == "" {
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(addonComponents)
+	if err != nil {
+		return fmt.Errorf("error reading addon components file %q: %v", addonComponents, err)
+	}
+
+	glog.Infof("Applying addon components from %s.", addonComponents)
+	objects, err := splitYAMLDocuments(raw)
+	if err != nil {
+		return err
+	}
+
+	dyn, mapper, err := newDynamicClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if err := serverSideApply(dyn, mapper, obj); err != nil {
+			return fmt.Errorf("error applying %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	sha := fmt.Sprintf("%x", sha256.Sum256(raw))
+	return recordAddonVersion(filepath.Base(addonComponents), sha)
+}
+
+// splitYAMLDocuments streams raw through a real YAML document decoder
+// rather than splitting on "---" by hand, so CRLF line endings and "---"
+// lines inside embedded block scalars (not unheard of in CNI/CSI manifests)
+// don't corrupt the split.
+func splitYAMLDocuments(raw []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(raw), 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error parsing addon document: %v", err)
+		}
+		if len(u.Object) == 0 || u.GetKind() == "" {
+			continue
+		}
+		objects = append(objects, u)
+	}
+	return objects, nil
+}
+
+func newDynamicClient(kubeconfig string) (dynamic.Interface, *restmapper.DeferredDiscoveryRESTMapper, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error building rest config: %v", err)
+	}
+
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating dynamic client: %v", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating discovery client: %v", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memoryCacheFor(discoveryClient))
+
+	return dyn, mapper, nil
+}
+
+// serverSideApply patches obj into the cluster using server-side apply
+// (a three-way merge against the existing object, or a create if it
+// doesn't exist yet), matching how the OpenStack CCM/Cinder CSI addons
+// need to be re-rolled on every minor upgrade.
+func serverSideApply(dyn dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("error mapping %s: %v", gvk, err)
+	}
+
+	var resource dynamic.ResourceInterface
+	if mapping.Scope.Name() == "namespace" {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = corev1.NamespaceDefault
+		}
+		resource = dyn.Resource(mapping.Resource).Namespace(namespace)
+	} else {
+		resource = dyn.Resource(mapping.Resource)
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = resource.Patch(obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: addonFieldManager,
+		Force:        boolPtr(true),
+	})
+	if apierrors.IsMethodNotSupported(err) {
+		// The API server predates server-side apply; fall back to create.
+		_, err = resource.Create(obj, metav1.CreateOptions{})
+	}
+	return err
+}
+
+// recordAddonVersion stashes sha under name in the addon-versions
+// ConfigMap so subsequent upgrades can diff what's already applied.
+func recordAddonVersion(name, sha string) error {
+	cm, err := kubeClientSet.CoreV1().ConfigMaps(corev1.NamespaceSystem).Get(addonVersionsConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      addonVersionsConfigMap,
+				Namespace: corev1.NamespaceSystem,
+			},
+			Data: map[string]string{},
+		}
+		cm.Data[name] = sha
+		_, err = kubeClientSet.CoreV1().ConfigMaps(corev1.NamespaceSystem).Create(cm)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[name] = sha
+	_, err = kubeClientSet.CoreV1().ConfigMaps(corev1.NamespaceSystem).Update(cm)
+	return err
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// memoryCacheFor adapts a plain discovery client to the CachedDiscoveryInterface
+// the deferred REST mapper expects; the upgrader is a short-lived CLI run so
+// there's no benefit to an on-disk cache.
+func memoryCacheFor(client discovery.DiscoveryInterface) discovery.CachedDiscoveryInterface {
+	return memcacheDiscoveryClient{client}
+}
+
+type memcacheDiscoveryClient struct {
+	discovery.DiscoveryInterface
+}
+
+func (c memcacheDiscoveryClient) Fresh() bool {
+	return false
+}
+
+func (c memcacheDiscoveryClient) Invalidate() {}
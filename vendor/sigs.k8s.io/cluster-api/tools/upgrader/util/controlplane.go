@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+)
+
+// upgradeControlPlane upgrades every master Machine in machines to
+// kubeversion, one at a time, only proceeding to the next master once the
+// remaining masters still report a healthy etcd quorum. HA clusters are
+// supported: single-master clusters are just the len(masters) == 1 case.
+func upgradeControlPlane(machines []clusterv1.Machine, kubeversion string) error {
+	var masters []*clusterv1.Machine
+	for i := range machines {
+		if util.IsMaster(&machines[i]) {
+			masters = append(masters, &machines[i])
+		}
+	}
+	if len(masters) == 0 {
+		return fmt.Errorf("No master is found.")
+	}
+
+	glog.Infof("Upgrading %d master(s) serially.", len(masters))
+	for i, master := range masters {
+		if err := ValidateControlPlaneSkew(master.Spec.Versions.ControlPlane, kubeversion); err != nil {
+			return err
+		}
+
+		glog.Infof("Upgrading master %s (%d/%d).", master.Name, i+1, len(masters))
+		master.Spec.Versions.Kubelet = kubeversion
+		master.Spec.Versions.ControlPlane = kubeversion
+		updated, err := machInterface.Update(master)
+		if err != nil {
+			return fmt.Errorf("error updating master %s: %v", master.Name, err)
+		}
+
+		if err := wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
+			ready, err := checkMachineReady(updated.Name, kubeversion)
+			if err != nil {
+				// Ignore the error as the master is restarting.
+				return false, nil
+			}
+			return ready, nil
+		}); err != nil {
+			return fmt.Errorf("master %s never became ready after upgrade: %v", master.Name, err)
+		}
+
+		if others := otherMasters(masters, master); len(others) > 0 {
+			glog.Info("Waiting for the remaining masters to confirm a healthy etcd quorum before continuing.")
+			if err := waitForEtcdQuorum(others, 5*time.Minute); err != nil {
+				return fmt.Errorf("etcd quorum is unhealthy after upgrading master %s, aborting remaining master upgrades: %v", master.Name, err)
+			}
+		}
+	}
+
+	glog.Info("Finished upgrading control plane.")
+	return nil
+}
+
+func otherMasters(masters []*clusterv1.Machine, exclude *clusterv1.Machine) []*clusterv1.Machine {
+	var others []*clusterv1.Machine
+	for _, m := range masters {
+		if m.Name != exclude.Name {
+			others = append(others, m)
+		}
+	}
+	return others
+}
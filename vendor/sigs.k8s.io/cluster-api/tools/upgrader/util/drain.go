@@ -0,0 +1,117 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// drainNode cordons nodeName and evicts every non-DaemonSet pod running on
+// it, respecting PodDisruptionBudgets, before returning.
+func drainNode(nodeName string, timeout time.Duration) error {
+	if err := cordonNode(nodeName); err != nil {
+		return err
+	}
+
+	pods, err := kubeClientSet.CoreV1().Pods(v1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing pods on node %s: %v", nodeName, err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if isDaemonSetPod(pod) {
+			glog.V(1).Infof("Skipping daemonset pod %s/%s.", pod.Namespace, pod.Name)
+			continue
+		}
+		if err := evictPod(pod, timeout); err != nil {
+			return fmt.Errorf("error evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func cordonNode(nodeName string) error {
+	node, err := kubeClientSet.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting node %s: %v", nodeName, err)
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	_, err = kubeClientSet.CoreV1().Nodes().Update(node)
+	return err
+}
+
+func isDaemonSetPod(pod *v1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// evictPod evicts pod via the eviction subresource, which honors
+// PodDisruptionBudgets, then waits for it to actually disappear from the
+// node.
+func evictPod(pod *v1.Pod, timeout time.Duration) error {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+
+	err := wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		err := kubeClientSet.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction)
+		switch {
+		case err == nil:
+			return true, nil
+		case apierrors.IsTooManyRequests(err):
+			// The PodDisruptionBudget doesn't allow an eviction yet; retry.
+			return false, nil
+		case apierrors.IsNotFound(err):
+			return true, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		_, err := kubeClientSet.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// waitForEtcdQuorum polls each of the given masters' /healthz/etcd endpoint
+// until all report a healthy quorum or timeout elapses, so the caller can
+// tell whether it's safe to upgrade the next master. On timeout the
+// returned error names the last master found degraded.
+func waitForEtcdQuorum(masters []*clusterv1.Machine, timeout time.Duration) error {
+	var degraded string
+
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
+		for _, master := range masters {
+			healthy, err := etcdHealthy(master)
+			if err != nil {
+				glog.V(1).Infof("Error checking etcd health via master %s: %v", master.Name, err)
+				degraded = master.Name
+				return false, nil
+			}
+			if !healthy {
+				glog.V(1).Infof("Master %s reports an unhealthy etcd quorum, retrying.", master.Name)
+				degraded = master.Name
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("master %s never reported a healthy etcd quorum: %v", degraded, err)
+	}
+	return nil
+}
+
+// etcdHealthy hits master's kube-apiserver /healthz/etcd endpoint directly
+// via its node's internal IP, so the check reflects that apiserver's own
+// view of etcd rather than whichever backend a load balancer happens to
+// route to.
+func etcdHealthy(master *clusterv1.Machine) (bool, error) {
+	machine, err := machInterface.Get(master.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if machine.Status.NodeRef == nil {
+		return false, fmt.Errorf("master %s has no node reference yet", master.Name)
+	}
+
+	node, err := kubeClientSet.CoreV1().Nodes().Get(machine.Status.NodeRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	address := nodeInternalIP(node)
+	if address == "" {
+		return false, fmt.Errorf("master %s has no internal IP", master.Name)
+	}
+
+	httpClient := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := httpClient.Get(fmt.Sprintf("https://%s:6443/healthz/etcd", address))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+func nodeInternalIP(node *v1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/pkg/util"
+)
+
+// Transition describes a single Machine's proposed move from its current
+// kubelet/control plane version to the target version.
+type Transition struct {
+	MachineName string
+	IsMaster    bool
+	FromVersion string
+	ToVersion   string
+	SkewWarning string
+}
+
+// Plan lists every Machine in the default namespace of the target cluster
+// (the same Machines `upgrade apply` acts on) and proposes the version
+// transition it would receive from an `upgrade apply --to-kubernetes
+// <toVersion>`, without mutating anything. Transitions that would violate
+// the kubelet/control-plane skew policy are still returned, with
+// SkewWarning explaining why `upgrade apply` would refuse them.
+func Plan(kubeconfig string, toVersion string) ([]Transition, error) {
+	if err := initClient(kubeconfig); err != nil {
+		return nil, err
+	}
+
+	machineList, err := machInterface.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	transitions := make([]Transition, 0, len(machineList.Items))
+	for i := range machineList.Items {
+		mach := &machineList.Items[i]
+		isMaster := util.IsMaster(mach)
+
+		t := Transition{
+			MachineName: mach.Name,
+			IsMaster:    isMaster,
+			FromVersion: mach.Spec.Versions.Kubelet,
+			ToVersion:   toVersion,
+		}
+
+		if isMaster {
+			if err := ValidateControlPlaneSkew(mach.Spec.Versions.ControlPlane, toVersion); err != nil {
+				t.SkewWarning = err.Error()
+			}
+		} else if err := ValidateKubeletSkew(toVersion, mach.Spec.Versions.ControlPlane); err != nil {
+			t.SkewWarning = err.Error()
+		}
+
+		transitions = append(transitions, t)
+	}
+
+	return transitions, nil
+}
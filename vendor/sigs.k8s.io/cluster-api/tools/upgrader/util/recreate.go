@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+)
+
+// upgradeWorkersRecreate replaces each worker with a new Machine running
+// options.KubeVersion, then drains and deletes the old one. options.MaxSurge
+// bounds how many replacements are being created at once; options.
+// MaxUnavailable bounds how many old workers are being drained at once.
+func upgradeWorkersRecreate(workers []clusterv1.Machine, options UpgradeOptions) error {
+	surge := make(chan struct{}, options.MaxSurge)
+	// A zero-capacity channel would make every recreateWorker goroutine
+	// block forever on its send to unavailable, so 0 (and any other
+	// non-positive value) is treated as 1 rather than deadlocking.
+	unavailableSlots := options.MaxUnavailable
+	if unavailableSlots <= 0 {
+		unavailableSlots = 1
+	}
+	unavailable := make(chan struct{}, unavailableSlots)
+
+	errors := make(chan error, len(workers))
+	for i := range workers {
+		go func(old *clusterv1.Machine) {
+			surge <- struct{}{}
+			defer func() { <-surge }()
+			errors <- recreateWorker(old, options, unavailable)
+		}(&workers[i])
+	}
+
+	var firstErr error
+	for range workers {
+		if err := <-errors; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	glog.Info("Successfully upgraded the cluster.")
+	return nil
+}
+
+// recreateWorker creates a replacement Machine for old, waits for it to
+// join, then drains and deletes old.
+func recreateWorker(old *clusterv1.Machine, options UpgradeOptions, unavailable chan struct{}) error {
+	replacement := old.DeepCopy()
+	replacement.Name = fmt.Sprintf("%s-%s", old.Name, options.KubeVersion)
+	replacement.ResourceVersion = ""
+	replacement.UID = ""
+	replacement.Status = clusterv1.MachineStatus{}
+	replacement.Spec.Versions.Kubelet = options.KubeVersion
+
+	glog.Infof("Creating replacement machine %s for %s.", replacement.Name, old.Name)
+	created, err := machInterface.Create(replacement)
+	if err != nil {
+		return fmt.Errorf("error creating replacement machine for %s: %v", old.Name, err)
+	}
+
+	if err := wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
+		ready, err := checkMachineReady(created.Name, options.KubeVersion)
+		if err != nil {
+			// Ignore the error as the new node is still joining.
+			return false, nil
+		}
+		return ready, nil
+	}); err != nil {
+		return fmt.Errorf("replacement machine %s for %s never became ready: %v", created.Name, old.Name, err)
+	}
+
+	unavailable <- struct{}{}
+	defer func() { <-unavailable }()
+
+	oldMachine, err := machInterface.Get(old.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting old machine %s: %v", old.Name, err)
+	}
+	if oldMachine.Status.NodeRef != nil {
+		glog.Infof("Draining node %s.", oldMachine.Status.NodeRef.Name)
+		if err := drainNode(oldMachine.Status.NodeRef.Name, options.DrainTimeout); err != nil {
+			return fmt.Errorf("error draining node for machine %s: %v", old.Name, err)
+		}
+	}
+
+	glog.Infof("Deleting old machine %s.", old.Name)
+	return machInterface.Delete(old.Name, &metav1.DeleteOptions{})
+}
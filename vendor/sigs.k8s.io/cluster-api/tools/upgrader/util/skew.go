@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// minorVersion is a parsed "vMAJOR.MINOR.PATCH" Kubernetes version.
+type minorVersion struct {
+	major int
+	minor int
+}
+
+func parseMinorVersion(version string) (minorVersion, error) {
+	trimmed := strings.TrimPrefix(version, "v")
+	parts := strings.SplitN(trimmed, ".", 3)
+	if len(parts) < 2 {
+		return minorVersion{}, fmt.Errorf("invalid kubernetes version %q", version)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return minorVersion{}, fmt.Errorf("invalid kubernetes version %q: %v", version, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return minorVersion{}, fmt.Errorf("invalid kubernetes version %q: %v", version, err)
+	}
+
+	return minorVersion{major: major, minor: minor}, nil
+}
+
+func (v minorVersion) minorDelta(other minorVersion) int {
+	return (v.major-other.major)*100 + (v.minor - other.minor)
+}
+
+// ValidateControlPlaneSkew ensures a control plane upgrade only moves one
+// minor version at a time, per the supported Kubernetes version skew policy.
+func ValidateControlPlaneSkew(current, target string) error {
+	c, err := parseMinorVersion(current)
+	if err != nil {
+		return err
+	}
+	t, err := parseMinorVersion(target)
+	if err != nil {
+		return err
+	}
+
+	delta := t.minorDelta(c)
+	if delta < 0 {
+		return fmt.Errorf("control plane version %s is newer than target %s", current, target)
+	}
+	if delta > 1 {
+		return fmt.Errorf("control plane upgrade from %s to %s skips a minor version; upgrade one minor at a time", current, target)
+	}
+
+	return nil
+}
+
+// ValidateKubeletSkew ensures a kubelet is no newer than the control plane
+// and at most one minor version older, per the supported Kubernetes version
+// skew policy.
+func ValidateKubeletSkew(kubeletVersion, controlPlaneVersion string) error {
+	k, err := parseMinorVersion(kubeletVersion)
+	if err != nil {
+		return err
+	}
+	cp, err := parseMinorVersion(controlPlaneVersion)
+	if err != nil {
+		return err
+	}
+
+	delta := cp.minorDelta(k)
+	if delta < 0 || delta > 1 {
+		return fmt.Errorf("kubelet version %s is not within one minor version of control plane %s", kubeletVersion, controlPlaneVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "testing"
+
+func TestParseMinorVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    minorVersion
+		wantErr bool
+	}{
+		{version: "v1.14.3", want: minorVersion{major: 1, minor: 14}},
+		{version: "1.14.3", want: minorVersion{major: 1, minor: 14}},
+		{version: "v1.14", want: minorVersion{major: 1, minor: 14}},
+		{version: "v1", wantErr: true},
+		{version: "vX.14.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMinorVersion(tt.version)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseMinorVersion(%q): expected an error, got none", tt.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMinorVersion(%q): unexpected error: %v", tt.version, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseMinorVersion(%q) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestValidateControlPlaneSkew(t *testing.T) {
+	tests := []struct {
+		current string
+		target  string
+		wantErr bool
+	}{
+		{current: "v1.14.0", target: "v1.14.5", wantErr: false},
+		{current: "v1.14.0", target: "v1.15.0", wantErr: false},
+		{current: "v1.14.0", target: "v1.16.0", wantErr: true},
+		{current: "v1.14.0", target: "v1.13.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateControlPlaneSkew(tt.current, tt.target)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ValidateControlPlaneSkew(%q, %q) error = %v, wantErr %v", tt.current, tt.target, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateKubeletSkew(t *testing.T) {
+	tests := []struct {
+		kubelet      string
+		controlPlane string
+		wantErr      bool
+	}{
+		{kubelet: "v1.14.0", controlPlane: "v1.14.5", wantErr: false},
+		{kubelet: "v1.13.0", controlPlane: "v1.14.0", wantErr: false},
+		{kubelet: "v1.14.0", controlPlane: "v1.13.0", wantErr: true},
+		{kubelet: "v1.12.0", controlPlane: "v1.14.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateKubeletSkew(tt.kubelet, tt.controlPlane)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ValidateKubeletSkew(%q, %q) error = %v, wantErr %v", tt.kubelet, tt.controlPlane, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "time"
+
+// Strategy controls how a worker Machine is moved onto a new Kubernetes
+// version.
+type Strategy string
+
+const (
+	// InPlace patches the existing Machine's kubelet version and waits for
+	// the actuator to restart kubelet on the existing node.
+	InPlace Strategy = "InPlace"
+	// Recreate replaces each worker with a new Machine running the target
+	// version, then drains and deletes the old one.
+	Recreate Strategy = "Recreate"
+)
+
+// UpgradeOptions configures an UpgradeClusterWithOptions call.
+type UpgradeOptions struct {
+	// KubeVersion is the Kubernetes version to upgrade the cluster to.
+	KubeVersion string
+	// Kubeconfig is the path to the kubeconfig of the cluster being
+	// upgraded. Defaults to util.GetDefaultKubeConfigPath() when empty.
+	Kubeconfig string
+	// Strategy selects how worker Machines are upgraded. Defaults to
+	// InPlace.
+	Strategy Strategy
+	// MaxSurge bounds how many replacement Machines the Recreate strategy
+	// may create above the existing worker count at once. Defaults to 1.
+	MaxSurge int
+	// MaxUnavailable bounds how many old workers the Recreate strategy may
+	// drain and delete at once; an old worker is only drained once its
+	// replacement is Ready. A value of 0 is treated as 1, since 0 would
+	// never drain any worker.
+	MaxUnavailable int
+	// DrainTimeout bounds how long the Recreate strategy waits on each pod
+	// of an old worker, once for its eviction to be accepted and once for
+	// it to disappear, before giving up on that Machine. Defaults to 5
+	// minutes.
+	DrainTimeout time.Duration
+	// AddonComponents is an optional path to a multi-document YAML file of
+	// cluster addons (CNI/CSI/CCM) to re-apply once the control plane has
+	// been upgraded and before workers roll, mirroring RunCreate's
+	// --addon-components.
+	AddonComponents string
+}
+
+func (o *UpgradeOptions) setDefaults() {
+	if o.Strategy == "" {
+		o.Strategy = InPlace
+	}
+	if o.MaxSurge <= 0 {
+		o.MaxSurge = 1
+	}
+	if o.DrainTimeout <= 0 {
+		o.DrainTimeout = 5 * time.Minute
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package util
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/golang/glog"
@@ -81,10 +80,18 @@ func checkMachineReady(machineName string, kubeVersion string) (bool, error) {
 	}
 }
 
-func UpgradeCluster(kubeversion string, kubeconfig string) error {
+// UpgradeClusterWithOptions upgrades every Machine in the default
+// namespace to options.KubeVersion, updating workers according to
+// options.Strategy.
+func UpgradeClusterWithOptions(options UpgradeOptions) error {
+	options.setDefaults()
+	if options.Kubeconfig == "" {
+		options.Kubeconfig = util.GetDefaultKubeConfigPath()
+	}
+	kubeversion := options.KubeVersion
 	glog.Infof("Starting to upgrade cluster to version: %s", kubeversion)
 
-	if err := initClient(kubeconfig); err != nil {
+	if err := initClient(options.Kubeconfig); err != nil {
 		return err
 	}
 
@@ -95,83 +102,72 @@ func UpgradeCluster(kubeversion string, kubeconfig string) error {
 
 	glog.Info("Upgrading the control plane.")
 
-	// Update the control plan first. It assumes single master.
-	var master *clusterv1.Machine = nil
-	for _, mach := range machine_list.Items {
-		if util.IsMaster(&mach) {
-			master = &mach
-			break
-		}
-	}
-
-	if master == nil {
-		err = fmt.Errorf("No master is found.")
-	} else {
-		master.Spec.Versions.Kubelet = kubeversion
-		master.Spec.Versions.ControlPlane = kubeversion
-		new_machine, err := machInterface.Update(master)
-		if err == nil {
-			err = wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
-				ready, err := checkMachineReady(new_machine.Name, kubeversion)
-				if err != nil {
-					// Ignore the error as control plan is restarting.
-					return false, nil
-				}
-				return ready, nil
-			})
-		}
+	if err := upgradeControlPlane(machine_list.Items, kubeversion); err != nil {
+		return err
 	}
 
-	if err != nil {
+	if err := applyAddons(options.Kubeconfig, options.AddonComponents); err != nil {
 		return err
 	}
 
-	glog.Info("Finished upgrading control plane.")
+	var workers []clusterv1.Machine
+	for i := range machine_list.Items {
+		if !util.IsMaster(&machine_list.Items[i]) {
+			workers = append(workers, machine_list.Items[i])
+		}
+	}
+	glog.Infof("Upgrading %d nodes in the cluster using the %s strategy.", len(workers), options.Strategy)
 
-	num_nodes := len(machine_list.Items) - 1
-	glog.Infof("Upgrading %d nodes in the cluster.", num_nodes)
+	switch options.Strategy {
+	case Recreate:
+		return upgradeWorkersRecreate(workers, options)
+	default:
+		return upgradeWorkersInPlace(workers, kubeversion)
+	}
+}
 
-	// Continue to update all the nodes.
-	errors := make(chan error, len(machine_list.Items))
-	for i, _ := range machine_list.Items {
-		if !util.IsMaster(&machine_list.Items[i]) {
-			go func(mach *clusterv1.Machine) {
-				glog.Infof("Upgrading %s.", mach.Name)
-				mach, err = machInterface.Get(mach.Name, metav1.GetOptions{})
+// upgradeWorkersInPlace patches every worker Machine's kubelet version and
+// waits for the actuator to restart kubelet on the existing node.
+func upgradeWorkersInPlace(workers []clusterv1.Machine, kubeversion string) error {
+	num_nodes := len(workers)
+	errors := make(chan error, len(workers))
+	for i := range workers {
+		go func(mach *clusterv1.Machine) {
+			glog.Infof("Upgrading %s.", mach.Name)
+			mach, err := machInterface.Get(mach.Name, metav1.GetOptions{})
+			if err == nil {
+				err = ValidateKubeletSkew(kubeversion, mach.Spec.Versions.ControlPlane)
+			}
+			if err == nil {
+				mach.Spec.Versions.Kubelet = kubeversion
+				new_machine, err := machInterface.Update(mach)
 				if err == nil {
-					mach.Spec.Versions.Kubelet = kubeversion
-					new_machine, err := machInterface.Update(mach)
-					if err == nil {
-						// Polling the cluster until nodes are updated.
-						err = wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
-							ready, err := checkMachineReady(new_machine.Name, kubeversion)
-							if err != nil {
-								// Ignore the error as control plan is restarting.
-								return false, nil
-							}
-							return ready, nil
-						})
-					} else {
-						glog.Errorf("Update to machine object (%s) failed : %v", mach.Name, err)
-					}
+					// Polling the cluster until nodes are updated.
+					err = wait.Poll(5*time.Second, 10*time.Minute, func() (bool, error) {
+						ready, err := checkMachineReady(new_machine.Name, kubeversion)
+						if err != nil {
+							// Ignore the error as control plan is restarting.
+							return false, nil
+						}
+						return ready, nil
+					})
 				} else {
-					glog.Errorf("client.Machines().Get() failed : %v", err)
+					glog.Errorf("Update to machine object (%s) failed : %v", mach.Name, err)
 				}
-				errors <- err
-			}(&machine_list.Items[i])
-		}
-	}
-
-	for _, machine := range machine_list.Items {
-		if !util.IsMaster(&machine) {
-			if err = <-errors; err != nil {
-				return err
 			} else {
-				num_nodes--
-				if num_nodes > 0 {
-					glog.Infof("%d nodes are still being updated", num_nodes)
-				}
+				glog.Errorf("client.Machines().Get() failed : %v", err)
 			}
+			errors <- err
+		}(&workers[i])
+	}
+
+	for range workers {
+		if err := <-errors; err != nil {
+			return err
+		}
+		num_nodes--
+		if num_nodes > 0 {
+			glog.Infof("%d nodes are still being updated", num_nodes)
 		}
 	}
 